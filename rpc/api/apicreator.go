@@ -0,0 +1,72 @@
+package api
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/tendermint/tendermint/libs/log"
+
+	sbchapi "github.com/smartbch/smartbch/api"
+)
+
+// APICreator builds the handler for one JSON-RPC namespace. Keeping creators
+// in a map keyed by namespace lets operators enable/disable namespaces
+// individually instead of always registering the full set. ks is the single
+// keystore shared by eth_ and personal_: geth's KeyStore keeps unlock state
+// in per-instance memory, not on disk, so two separate KeyStore instances
+// over the same directory would never see each other's unlocks.
+type APICreator func(backend sbchapi.BackendService, cfg Config, logger log.Logger, ks *keystore.KeyStore) interface{}
+
+// Config carries the namespace-specific options operators can set, e.g.
+// where the personal_ keystore lives. TestKeys is carried along but is not
+// read by any of the creators below; no test accounts are loaded from it.
+type Config struct {
+	KeystoreDir string
+	TestKeys    []string
+}
+
+// apiCreators is the full set of namespaces this node knows how to serve.
+var apiCreators = map[string]APICreator{
+	"eth": func(backend sbchapi.BackendService, cfg Config, logger log.Logger, ks *keystore.KeyStore) interface{} {
+		return newEthAPI(backend, ks, logger)
+	},
+	"eth_filter": func(backend sbchapi.BackendService, cfg Config, logger log.Logger, ks *keystore.KeyStore) interface{} {
+		return newFilterAPI(backend, logger)
+	},
+	"personal": func(backend sbchapi.BackendService, cfg Config, logger log.Logger, ks *keystore.KeyStore) interface{} {
+		return newPersonalAPI(backend, ks, logger)
+	},
+	"net": func(backend sbchapi.BackendService, cfg Config, logger log.Logger, ks *keystore.KeyStore) interface{} {
+		return newNetAPI(backend)
+	},
+	"web3": func(backend sbchapi.BackendService, cfg Config, logger log.Logger, ks *keystore.KeyStore) interface{} {
+		return newWeb3API()
+	},
+	"txpool": func(backend sbchapi.BackendService, cfg Config, logger log.Logger, ks *keystore.KeyStore) interface{} {
+		return newTxPoolAPI()
+	},
+	"miner": func(backend sbchapi.BackendService, cfg Config, logger log.Logger, ks *keystore.KeyStore) interface{} {
+		return newMinerAPI(backend)
+	},
+	"debug": func(backend sbchapi.BackendService, cfg Config, logger log.Logger, ks *keystore.KeyStore) interface{} {
+		return newDebugAPI(backend, logger)
+	},
+}
+
+// GetAPIs returns the handlers for the requested namespaces, in the order
+// given, skipping any name that has no registered creator. The keystore
+// directory is opened exactly once here and the resulting *keystore.KeyStore
+// is handed to every namespace that needs it, so eth_resend sees the same
+// unlock state personal_unlockAccount just set.
+func GetAPIs(backend sbchapi.BackendService, cfg Config, logger log.Logger, namespaces []string) []interface{} {
+	ks := newKeystoreAt(cfg.KeystoreDir)
+
+	apis := make([]interface{}, 0, len(namespaces))
+	for _, ns := range namespaces {
+		creator, found := apiCreators[ns]
+		if !found {
+			logger.Error("unknown RPC namespace, skipping", "namespace", ns)
+			continue
+		}
+		apis = append(apis, creator(backend, cfg, logger, ks))
+	}
+	return apis
+}
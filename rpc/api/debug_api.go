@@ -1,15 +1,81 @@
 package api
 
 import (
+	"encoding/json"
 	"runtime"
 	"sync/atomic"
 	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/tendermint/tendermint/libs/log"
+
+	sbchapi "github.com/smartbch/smartbch/api"
+	rpctypes "github.com/smartbch/smartbch/rpc/internal/ethapi"
 )
 
 const (
 	StatusUpdateInterval = 60 // seconds
+
+	// DefaultTraceTimeout bounds how long a single debug_trace* call may
+	// run before MoeingEVM aborts it, matching geth's default.
+	DefaultTraceTimeout = 5 * time.Second
+
+	// Built-in tracer names accepted by TraceConfig.Tracer.
+	CallTracer     = "callTracer"
+	PrestateTracer = "prestateTracer"
+	FourByteTracer = "4byteTracer"
 )
 
+// TraceConfig mirrors geth's debug_traceTransaction/debug_traceCall config
+// object: name a built-in tracer (or a JS tracer body) to get tracer-shaped
+// JSON back, or leave Tracer empty to get the raw struct-log stream.
+type TraceConfig struct {
+	Tracer         *string         `json:"tracer"`
+	TracerConfig   json.RawMessage `json:"tracerConfig"`
+	Timeout        *string         `json:"timeout"`
+	DisableStack   bool            `json:"disableStack"`
+	DisableStorage bool            `json:"disableStorage"`
+	DisableMemory  bool            `json:"disableMemory"`
+}
+
+// traceTimeout parses cfg.Timeout the way geth does ("5s", "500ms", ...),
+// falling back to DefaultTraceTimeout for a nil config or empty string.
+func (cfg *TraceConfig) traceTimeout() time.Duration {
+	if cfg == nil || cfg.Timeout == nil || *cfg.Timeout == "" {
+		return DefaultTraceTimeout
+	}
+	if d, err := time.ParseDuration(*cfg.Timeout); err == nil {
+		return d
+	}
+	return DefaultTraceTimeout
+}
+
+// StructLogRes is one entry of the default (no Tracer set) opcode-by-opcode
+// trace, populated from the EVMLogger-shaped callback MoeingEVM invokes on
+// every step: (pc, op, gas, cost, scope, depth, err).
+type StructLogRes struct {
+	Pc      uint64            `json:"pc"`
+	Op      string            `json:"op"`
+	Gas     uint64            `json:"gas"`
+	GasCost uint64            `json:"gasCost"`
+	Depth   int               `json:"depth"`
+	Error   string            `json:"error,omitempty"`
+	Stack   []string          `json:"stack,omitempty"`
+	Memory  []string          `json:"memory,omitempty"`
+	Storage map[string]string `json:"storage,omitempty"`
+}
+
+// ExecutionResult is the default tracer's envelope, matching geth's shape
+// for debug_traceTransaction when no named Tracer is given.
+type ExecutionResult struct {
+	Gas         uint64         `json:"gas"`
+	Failed      bool           `json:"failed"`
+	ReturnValue string         `json:"returnValue"`
+	StructLogs  []StructLogRes `json:"structLogs"`
+}
+
 type Stats struct {
 	NumGoroutine int    `json:"numGoroutine"`
 	NumGC        uint32 `json:"numGC"`
@@ -17,15 +83,27 @@ type Stats struct {
 	MemSysMB     uint64 `json:"memSysMB"`
 }
 
+// DebugAPI is the `debug_` namespace: Go runtime stats plus a geth-compatible
+// tracing surface for block explorers and Tenderly-style debuggers.
 type DebugAPI interface {
 	GetStats() Stats
+	TraceTransaction(hash common.Hash, cfg *TraceConfig) (interface{}, error)
+	TraceBlockByNumber(blockNr gethrpc.BlockNumber, cfg *TraceConfig) ([]interface{}, error)
+	TraceBlockByHash(hash common.Hash, cfg *TraceConfig) ([]interface{}, error)
+	TraceCall(args rpctypes.CallArgs, blockNr gethrpc.BlockNumber, cfg *TraceConfig) (interface{}, error)
 }
 
-func newDebugAPI() DebugAPI {
-	return &debugAPI{}
+func newDebugAPI(backend sbchapi.BackendService, logger log.Logger) DebugAPI {
+	return &debugAPI{
+		backend: backend,
+		logger:  logger.With("module", "debug-api"),
+	}
 }
 
 type debugAPI struct {
+	backend sbchapi.BackendService
+	logger  log.Logger
+
 	lastUpdateTime int64
 	stats          Stats
 }
@@ -51,3 +129,79 @@ func (api *debugAPI) updateStats() {
 	api.stats.MemAllocMB = memStats.Alloc / 1024 / 1024
 	api.stats.MemSysMB = memStats.Sys / 1024 / 1024
 }
+
+// https://geth.ethereum.org/docs/rpc/ns-debug#debug_tracetransaction
+//
+// Re-executes hash's transaction on top of its block's pre-state snapshot,
+// with MoeingEVM invoking the tracer's EVMLogger-shaped callback on every
+// opcode, and returns either the tracer-shaped JSON (cfg.Tracer set) or the
+// default struct-log stream.
+func (api *debugAPI) TraceTransaction(hash common.Hash, cfg *TraceConfig) (interface{}, error) {
+	return api.backend.TraceTx(hash, traceOptsFromConfig(cfg))
+}
+
+// https://geth.ethereum.org/docs/rpc/ns-debug#debug_traceblockbynumber
+func (api *debugAPI) TraceBlockByNumber(blockNr gethrpc.BlockNumber, cfg *TraceConfig) ([]interface{}, error) {
+	height, err := api.resolveTraceHeight(blockNr)
+	if err != nil {
+		return nil, err
+	}
+	return api.backend.TraceBlock(height, traceOptsFromConfig(cfg))
+}
+
+// https://geth.ethereum.org/docs/rpc/ns-debug#debug_traceblockbyhash
+func (api *debugAPI) TraceBlockByHash(hash common.Hash, cfg *TraceConfig) ([]interface{}, error) {
+	block, err := api.backend.BlockByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	return api.backend.TraceBlock(int64(block.Number), traceOptsFromConfig(cfg))
+}
+
+// https://geth.ethereum.org/docs/rpc/ns-debug#debug_tracecall
+//
+// Like eth_call, but the call is run with the tracer attached instead of
+// being discarded after a plain success/revert check.
+func (api *debugAPI) TraceCall(args rpctypes.CallArgs, blockNr gethrpc.BlockNumber, cfg *TraceConfig) (interface{}, error) {
+	height, err := api.resolveTraceHeight(blockNr)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, from, err := createGethTxFromCallArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	return api.backend.TraceCall(tx, from, height, traceOptsFromConfig(cfg))
+}
+
+func (api *debugAPI) resolveTraceHeight(blockNr gethrpc.BlockNumber) (int64, error) {
+	height := blockNr.Int64()
+	if height <= 0 {
+		return int64(api.backend.LatestHeight()), nil
+	}
+	if height > int64(api.backend.LatestHeight()) {
+		return 0, ErrHistoricalStateNotAvailable
+	}
+	return height, nil
+}
+
+// traceOptsFromConfig translates the RPC-facing TraceConfig into the
+// sbchapi.TraceOptions shape BackendService's tracing methods expect.
+func traceOptsFromConfig(cfg *TraceConfig) sbchapi.TraceOptions {
+	if cfg == nil {
+		cfg = &TraceConfig{}
+	}
+
+	opts := sbchapi.TraceOptions{
+		TracerConfig:   cfg.TracerConfig,
+		Timeout:        cfg.traceTimeout(),
+		DisableStack:   cfg.DisableStack,
+		DisableStorage: cfg.DisableStorage,
+		DisableMemory:  cfg.DisableMemory,
+	}
+	if cfg.Tracer != nil {
+		opts.Tracer = *cfg.Tracer
+	}
+	return opts
+}
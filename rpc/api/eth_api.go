@@ -1,15 +1,15 @@
 package api
 
 import (
-	"crypto/ecdsa"
 	"errors"
+	"fmt"
 	"math/big"
-	"sort"
 
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	gethtypes "github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	gethrpc "github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/tendermint/tendermint/libs/log"
@@ -27,14 +27,33 @@ const (
 	DefaultGasPrice = 20000000000
 	// DefaultRPCGasLimit is default gas limit for RPC call operations
 	DefaultRPCGasLimit = 10000000
+
+	// DefaultGasPriceBumpPercent is how much higher eth_resend's
+	// replacement gasPrice must be than the original, same default geth uses.
+	DefaultGasPriceBumpPercent = 10
+
+	// DefaultMaxTxFeeBCH caps the fee (gasPrice * gasLimit) eth_resend and
+	// eth_sendRawTransaction will accept, as a foot-gun guard against a
+	// fat-fingered gasPrice; expressed in BCH (1 BCH = 1e18 wei, same
+	// decimals as the chain's native token).
+	DefaultMaxTxFeeBCH = 1
 )
 
+// weiPerBCH is 1e18, used to convert DefaultMaxTxFeeBCH into wei for
+// CheckTxFee's comparison against gasPrice * gasLimit.
+var weiPerBCH = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+
+// ErrHistoricalStateNotAvailable is returned when a request targets a block
+// height that is older than the oldest state snapshot MoeingADS still
+// retains, e.g. a pruned archive height.
+var ErrHistoricalStateNotAvailable = errors.New("historical state not available")
+
 var _ PublicEthAPI = (*ethAPI)(nil)
 
 type PublicEthAPI interface {
-	Accounts() ([]common.Address, error)
 	BlockNumber() (hexutil.Uint64, error)
-	Call(args rpctypes.CallArgs, blockNr gethrpc.BlockNumber) (hexutil.Bytes, error)
+	Call(args rpctypes.CallArgs, blockNr gethrpc.BlockNumber, overrides *StateOverride, blockOverrides *BlockOverrides) (hexutil.Bytes, error)
+	CallMany(bundles []rpctypes.CallArgs, overrides *StateOverride, blockOverrides *BlockOverrides) ([]CallManyResult, error)
 	ChainId() hexutil.Uint64
 	Coinbase() (common.Address, error)
 	EstimateGas(args rpctypes.CallArgs) (hexutil.Uint64, error)
@@ -45,6 +64,7 @@ type PublicEthAPI interface {
 	GetBlockTransactionCountByHash(hash common.Hash) *hexutil.Uint
 	GetBlockTransactionCountByNumber(blockNum gethrpc.BlockNumber) *hexutil.Uint
 	GetCode(addr common.Address, blockNum gethrpc.BlockNumber) (hexutil.Bytes, error)
+	GetProof(addr common.Address, storageKeys []string, blockNum gethrpc.BlockNumber) (*AccountResult, error)
 	GetStorageAt(addr common.Address, key string, blockNum gethrpc.BlockNumber) (hexutil.Bytes, error)
 	GetTransactionByBlockHashAndIndex(hash common.Hash, idx hexutil.Uint) (*rpctypes.Transaction, error)
 	GetTransactionByBlockNumberAndIndex(blockNum gethrpc.BlockNumber, idx hexutil.Uint) (*rpctypes.Transaction, error)
@@ -57,56 +77,25 @@ type PublicEthAPI interface {
 	GetUncleCountByBlockNumber(_ gethrpc.BlockNumber) hexutil.Uint
 	ProtocolVersion() hexutil.Uint
 	SendRawTransaction(data hexutil.Bytes) (common.Hash, error) // ?
-	SendTransaction(args rpctypes.SendTxArgs) (common.Hash, error)
 	Syncing() (interface{}, error)
+	FillTransaction(args rpctypes.SendTxArgs) (map[string]interface{}, error)
+	Resend(tx rpctypes.SendTxArgs, gasPrice *hexutil.Big, gasLimit *hexutil.Uint64) (common.Hash, error)
 }
 
 type ethAPI struct {
 	backend  sbchapi.BackendService
-	accounts map[common.Address]*ecdsa.PrivateKey // only for test
+	keystore *keystore.KeyStore // only used to re-sign for Resend
 	logger   log.Logger
 }
 
-func newEthAPI(backend sbchapi.BackendService, testKeys []string, logger log.Logger) *ethAPI {
+func newEthAPI(backend sbchapi.BackendService, ks *keystore.KeyStore, logger log.Logger) *ethAPI {
 	return &ethAPI{
 		backend:  backend,
-		accounts: loadTestAccounts(testKeys, logger),
+		keystore: ks,
 		logger:   logger.With("module", "eth-api"),
 	}
 }
 
-func loadTestAccounts(testKeys []string, logger log.Logger) map[common.Address]*ecdsa.PrivateKey {
-	accs := make(map[common.Address]*ecdsa.PrivateKey, len(testKeys))
-	for _, testKey := range testKeys {
-		if key, _, err := ethutils.HexToPrivKey(testKey); err == nil {
-			addr := crypto.PubkeyToAddress(key.PublicKey)
-			accs[addr] = key
-		} else {
-			logger.Error("failed to load private key:", testKey, err.Error())
-		}
-	}
-	return accs
-}
-
-func (api *ethAPI) Accounts() ([]common.Address, error) {
-	addrs := make([]common.Address, 0, len(api.accounts))
-	for addr := range api.accounts {
-		addrs = append(addrs, addr)
-	}
-
-	sort.Slice(addrs, func(i, j int) bool {
-		for k := 0; k < common.AddressLength; k++ {
-			if addrs[i][k] < addrs[j][k] {
-				return true
-			} else if addrs[i][k] > addrs[j][k] {
-				return false
-			}
-		}
-		return false
-	})
-	return addrs, nil
-}
-
 // https://eth.wiki/json-rpc/API#eth_blockNumber
 func (api *ethAPI) BlockNumber() (hexutil.Uint64, error) {
 	return hexutil.Uint64(api.backend.LatestHeight()), nil
@@ -131,8 +120,12 @@ func (api *ethAPI) GasPrice() *hexutil.Big {
 
 // https://eth.wiki/json-rpc/API#eth_getBalance
 func (api *ethAPI) GetBalance(addr common.Address, blockNum gethrpc.BlockNumber) (*hexutil.Big, error) {
-	// ignore blockNumber temporary
-	b, err := api.backend.GetBalance(addr, int64(gethrpc.LatestBlockNumber))
+	height, err := api.resolveBlockHeight(blockNum)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := api.backend.GetBalance(addr, height)
 	if err != nil {
 		if err == types.ErrAccNotFound {
 			return (*hexutil.Big)(big.NewInt(0)), nil
@@ -144,17 +137,28 @@ func (api *ethAPI) GetBalance(addr common.Address, blockNum gethrpc.BlockNumber)
 
 // https://eth.wiki/json-rpc/API#eth_getCode
 func (api *ethAPI) GetCode(addr common.Address, blockNum gethrpc.BlockNumber) (hexutil.Bytes, error) {
-	// ignore blockNumber temporary
-	code, _ := api.backend.GetCode(addr, int64(gethrpc.LatestBlockNumber))
+	height, err := api.resolveBlockHeight(blockNum)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := api.backend.GetCode(addr, height)
+	if err != nil {
+		return nil, err
+	}
 	return code, nil
 }
 
 // https://eth.wiki/json-rpc/API#eth_getStorageAt
 func (api *ethAPI) GetStorageAt(addr common.Address, key string, blockNum gethrpc.BlockNumber) (hexutil.Bytes, error) {
-	// ignore blockNumber temporary
+	height, err := api.resolveBlockHeight(blockNum)
+	if err != nil {
+		return nil, err
+	}
+
 	hash := common.HexToHash(key)
 	key = string(hash[:])
-	return api.backend.GetStorageAt(addr, key, int64(gethrpc.LatestBlockNumber)), nil
+	return api.backend.GetStorageAt(addr, key, height), nil
 }
 
 // https://eth.wiki/json-rpc/API#eth_getBlockByHash
@@ -247,8 +251,12 @@ func (api *ethAPI) GetTransactionByHash(hash common.Hash) (*rpctypes.Transaction
 
 // https://eth.wiki/json-rpc/API#eth_getTransactionCount
 func (api *ethAPI) GetTransactionCount(addr common.Address, blockNum gethrpc.BlockNumber) (*hexutil.Uint64, error) {
-	// ignore blockNumber temporary
-	nonce, err := api.backend.GetNonce(addr)
+	height, err := api.resolveBlockHeight(blockNum)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := api.backend.GetNonceAt(addr, height)
 	if err != nil {
 		return nil, err
 	}
@@ -265,6 +273,38 @@ func (api *ethAPI) getBlockByNum(blockNum gethrpc.BlockNumber) (*types.Block, er
 	return api.backend.BlockByNumber(height)
 }
 
+// resolveBlockHeight turns the go-ethereum block-number parameter accepted by
+// the JSON-RPC methods into the MoeingADS height expected by BackendService.
+// "pending" is served from the latest committed height since smartbch has no
+// speculative mempool state, and "earliest" maps to the first block. A
+// positive height beyond the chain's current height, or older than the
+// oldest height MoeingADS still has a snapshot for, surfaces as
+// ErrHistoricalStateNotAvailable instead of silently falling back to latest.
+func (api *ethAPI) resolveBlockHeight(blockNum gethrpc.BlockNumber) (int64, error) {
+	switch blockNum {
+	case gethrpc.PendingBlockNumber, gethrpc.LatestBlockNumber:
+		return int64(gethrpc.LatestBlockNumber), nil
+	case gethrpc.EarliestBlockNumber:
+		return 1, nil
+	default:
+		latest := int64(api.backend.LatestHeight())
+		oldest := int64(api.backend.OldestHeight())
+		return resolveHeightBounds(blockNum, latest, oldest)
+	}
+}
+
+// resolveHeightBounds holds the actual range check out of resolveBlockHeight
+// as a pure function so it can be unit-tested without a live backend: a
+// height must be neither ahead of the chain's tip nor older than the oldest
+// snapshot MoeingADS still retains.
+func resolveHeightBounds(blockNum gethrpc.BlockNumber, latest, oldest int64) (int64, error) {
+	height := blockNum.Int64()
+	if height > latest || height < oldest {
+		return 0, ErrHistoricalStateNotAvailable
+	}
+	return height, nil
+}
+
 func (api *ethAPI) getTxByIdx(block *types.Block, idx hexutil.Uint) (*rpctypes.Transaction, error) {
 	if uint64(idx) >= uint64(len(block.Transactions)) {
 		// return if index out of bounds
@@ -326,50 +366,16 @@ func (api *ethAPI) SendRawTransaction(data hexutil.Bytes) (common.Hash, error) {
 		return common.Hash{}, err
 	}
 
-	tmTxHash, err := api.backend.SendRawTx(data)
-	if err != nil {
-		return tmTxHash, err
-	}
-
-	return tx.Hash(), nil
-}
-
-// https://eth.wiki/json-rpc/API#eth_sendTransaction
-func (api *ethAPI) SendTransaction(args rpctypes.SendTxArgs) (common.Hash, error) {
-	privKey, found := api.accounts[args.From]
-	if !found {
-		return common.Hash{}, errors.New("unknown account: " + args.From.Hex())
-	}
-
-	if args.Nonce == nil {
-		if nonce, err := api.backend.GetNonce(args.From); err == nil {
-			args.Nonce = (*hexutil.Uint64)(&nonce)
-		}
-	}
-
-	tx, err := createGethTxFromSendTxArgs(args)
-	if err != nil {
-		return common.Hash{}, err
-	}
-
-	chainID := api.backend.ChainId()
-	tx, err = ethutils.SignTx(tx, chainID, privKey)
-	if err != nil {
+	if err := CheckTxFee(tx.GasPrice(), tx.Gas(), DefaultMaxTxFeeBCH); err != nil {
 		return common.Hash{}, err
 	}
 
-	txBytes, err := ethutils.EncodeTx(tx)
-	if err != nil {
-		return common.Hash{}, err
-	}
-
-	tmTxHash, err := api.backend.SendRawTx(txBytes)
+	tmTxHash, err := api.backend.SendRawTx(data)
 	if err != nil {
 		return tmTxHash, err
 	}
 
-	txHash := tx.Hash()
-	return txHash, err
+	return tx.Hash(), nil
 }
 
 // https://eth.wiki/json-rpc/API#eth_syncing
@@ -391,15 +397,30 @@ func (api *ethAPI) Syncing() (interface{}, error) {
 	}, nil
 }
 
-// https://eth.wiki/json-rpc/API#eth_call
-func (api *ethAPI) Call(args rpctypes.CallArgs, blockNr gethrpc.BlockNumber) (hexutil.Bytes, error) {
-	// ignore blockNumber temporary
-	tx, from, err := api.createGethTxFromCallArgs(args)
+// https://eth.wiki/json-rpc/API#eth_call, extended with geth's optional
+// state/block-override params: overrides lets the caller inject synthetic
+// code/balance/storage for a set of addresses, and blockOverrides lets it
+// bump header fields (timestamp, baseFee, ...) a contract might branch on.
+// Both are applied to a forked copy of the target height's state and never
+// persist past this one call.
+func (api *ethAPI) Call(
+	args rpctypes.CallArgs,
+	blockNr gethrpc.BlockNumber,
+	overrides *StateOverride,
+	blockOverrides *BlockOverrides,
+) (hexutil.Bytes, error) {
+	height, err := api.resolveBlockHeight(blockNr)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+
+	tx, from, err := createGethTxFromCallArgs(args)
 	if err != nil {
 		return hexutil.Bytes{}, err
 	}
 
-	statusCode, retData := api.backend.Call(tx, from)
+	statusCode, retData := api.backend.CallAtHeightWithOverrides(
+		tx, from, height, toBackendStateOverride(overrides), toBackendBlockOverrides(blockOverrides))
 	if !ebp.StatusIsFailure(statusCode) {
 		return retData, nil
 	}
@@ -409,7 +430,7 @@ func (api *ethAPI) Call(args rpctypes.CallArgs, blockNr gethrpc.BlockNumber) (he
 
 // https://eth.wiki/json-rpc/API#eth_estimateGas
 func (api *ethAPI) EstimateGas(args rpctypes.CallArgs) (hexutil.Uint64, error) {
-	tx, from, err := api.createGethTxFromCallArgs(args)
+	tx, from, err := createGethTxFromCallArgs(args)
 	if err != nil {
 		return 0, err
 	}
@@ -422,7 +443,10 @@ func (api *ethAPI) EstimateGas(args rpctypes.CallArgs) (hexutil.Uint64, error) {
 	return 0, toCallErr(statusCode, retData)
 }
 
-func (api *ethAPI) createGethTxFromCallArgs(args rpctypes.CallArgs,
+// createGethTxFromCallArgs builds an unsigned tx out of eth_call/eth_estimateGas
+// style arguments. It is a free function (not a *ethAPI method) since
+// debugAPI's TraceCall needs it too and it never touches API state.
+func createGethTxFromCallArgs(args rpctypes.CallArgs,
 ) (*gethtypes.Transaction, common.Address, error) {
 
 	var from, to common.Address
@@ -466,3 +490,147 @@ func (api *ethAPI) createGethTxFromCallArgs(args rpctypes.CallArgs,
 	tx := gethtypes.NewTransaction(0, to, val, gasLimit, gasPrice, data)
 	return tx, from, nil
 }
+
+// https://geth.ethereum.org/docs/rpc/ns-eth#eth_filltransaction
+//
+// FillTransaction normalizes nonce/gas/gasPrice/chainID the same way
+// SendTransaction does, but returns the raw unsigned tx instead of
+// submitting it, so a wallet can sign it externally and broadcast later.
+func (api *ethAPI) FillTransaction(args rpctypes.SendTxArgs) (map[string]interface{}, error) {
+	if args.Nonce == nil {
+		if nonce, err := api.backend.GetNonce(args.From); err == nil {
+			args.Nonce = (*hexutil.Uint64)(&nonce)
+		}
+	}
+
+	tx, err := createGethTxFromSendTxArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := ethutils.EncodeTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"raw": hexutil.Bytes(raw),
+		"tx": map[string]interface{}{
+			"from":     args.From,
+			"to":       args.To,
+			"gas":      hexutil.Uint64(tx.Gas()),
+			"gasPrice": (*hexutil.Big)(tx.GasPrice()),
+			"value":    (*hexutil.Big)(tx.Value()),
+			"nonce":    hexutil.Uint64(tx.Nonce()),
+			"input":    hexutil.Bytes(tx.Data()),
+			"chainId":  (*hexutil.Big)(api.backend.ChainId()),
+		},
+	}, nil
+}
+
+// https://geth.ethereum.org/docs/rpc/ns-eth#eth_resend
+//
+// Resend looks up a still-pending tx from (from, nonce), builds a
+// replacement with the caller's new gas parameters, re-signs it with the
+// matching key from the eth_ keystore (which must already be unlocked via
+// personal_unlockAccount), and submits it in place of the original.
+func (api *ethAPI) Resend(args rpctypes.SendTxArgs, gasPrice *hexutil.Big, gasLimit *hexutil.Uint64) (common.Hash, error) {
+	if args.Nonce == nil {
+		return common.Hash{}, errors.New("missing nonce for tx to resend")
+	}
+
+	oldTx, err := api.findPendingTx(args.From, uint64(*args.Nonce))
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	newGasPrice := oldTx.GasPrice()
+	if gasPrice != nil {
+		newGasPrice = gasPrice.ToInt()
+	}
+	minBumpedPrice := bumpByPercent(oldTx.GasPrice(), DefaultGasPriceBumpPercent)
+	if newGasPrice.Cmp(minBumpedPrice) < 0 {
+		return common.Hash{}, fmt.Errorf(
+			"replacement gasPrice %s must exceed original %s by at least %d%%",
+			newGasPrice, oldTx.GasPrice(), DefaultGasPriceBumpPercent)
+	}
+
+	newGasLimit := oldTx.Gas()
+	if gasLimit != nil {
+		newGasLimit = uint64(*gasLimit)
+	}
+
+	if err := CheckTxFee(newGasPrice, newGasLimit, DefaultMaxTxFeeBCH); err != nil {
+		return common.Hash{}, err
+	}
+
+	var replacement *gethtypes.Transaction
+	if oldTx.To() == nil {
+		replacement = gethtypes.NewContractCreation(oldTx.Nonce(), oldTx.Value(), newGasLimit, newGasPrice, oldTx.Data())
+	} else {
+		replacement = gethtypes.NewTransaction(oldTx.Nonce(), *oldTx.To(), oldTx.Value(), newGasLimit, newGasPrice, oldTx.Data())
+	}
+
+	chainID := api.backend.ChainId()
+	acc := accounts.Account{Address: args.From}
+	signed, err := api.keystore.SignTx(acc, replacement, chainID)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to sign replacement tx for %s (is the account unlocked?): %w", args.From.Hex(), err)
+	}
+
+	txBytes, err := ethutils.EncodeTx(signed)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return api.backend.SendRawTx(txBytes)
+}
+
+// findPendingTx scans the Tendermint mempool for a still-pending tx from
+// addr with the given nonce, the same (from, nonce) lookup eth_resend needs.
+func (api *ethAPI) findPendingTx(addr common.Address, nonce uint64) (*gethtypes.Transaction, error) {
+	result, err := tmrpc.UnconfirmedTxs(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, raw := range result.Txs {
+		tx, err := ethutils.DecodeTx(raw)
+		if err != nil {
+			continue
+		}
+		from, err := ethutils.Sender(tx)
+		if err != nil || from != addr {
+			continue
+		}
+		if tx.Nonce() == nonce {
+			return tx, nil
+		}
+	}
+	return nil, errors.New("no pending transaction found for resend")
+}
+
+// bumpByPercent returns price increased by percent%, rounding down.
+func bumpByPercent(price *big.Int, percent int64) *big.Int {
+	bumped := new(big.Int).Mul(price, big.NewInt(100+percent))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+// CheckTxFee rejects absurdly high fees (gasPrice * gasLimit exceeding
+// capBCH, expressed in BCH) before the node signs or re-broadcasts a tx, as
+// a guard against a fat-fingered gasPrice taking out the whole balance.
+func CheckTxFee(gasPrice *big.Int, gasLimit uint64, capBCH float64) error {
+	if capBCH <= 0 {
+		return nil // fee cap disabled
+	}
+
+	fee := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasLimit))
+	feeFloat := new(big.Float).SetInt(fee)
+	feeFloat.Quo(feeFloat, new(big.Float).SetInt(weiPerBCH))
+
+	feeCap := big.NewFloat(capBCH)
+	if feeFloat.Cmp(feeCap) > 0 {
+		return fmt.Errorf("tx fee (%s BCH) exceeds the configured cap of %v BCH", feeFloat.String(), capBCH)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestBumpByPercent(t *testing.T) {
+	cases := []struct {
+		price   int64
+		percent int64
+		want    int64
+	}{
+		{100, 10, 110},
+		{100, 0, 100},
+		{1, 10, 1}, // rounds down: 1*110/100 = 1
+		{1000000000, 10, 1100000000},
+	}
+
+	for _, tc := range cases {
+		got := bumpByPercent(big.NewInt(tc.price), tc.percent)
+		if got.Cmp(big.NewInt(tc.want)) != 0 {
+			t.Fatalf("bumpByPercent(%d, %d) = %s, want %d", tc.price, tc.percent, got, tc.want)
+		}
+	}
+}
+
+// TestCheckTxFee is the fee-cap conformance check the eth_resend/
+// eth_sendRawTransaction request asked for: a fee at or under the cap passes,
+// anything over it is rejected, and a non-positive cap disables the guard
+// entirely (the "no cap configured" escape hatch).
+func TestCheckTxFee(t *testing.T) {
+	oneGwei := big.NewInt(1000000000)
+
+	cases := []struct {
+		name     string
+		gasPrice *big.Int
+		gasLimit uint64
+		capBCH   float64
+		wantErr  bool
+	}{
+		{"well under cap", oneGwei, 21000, 1, false},
+		{"exactly at cap", weiPerBCH, 1, 1, false},
+		{"just over cap", new(big.Int).Add(weiPerBCH, big.NewInt(1)), 1, 1, true},
+		{"way over cap", weiPerBCH, 1000000, 1, true},
+		{"cap disabled", weiPerBCH, 1000000, 0, false},
+		{"negative cap also disables", weiPerBCH, 1000000, -1, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckTxFee(tc.gasPrice, tc.gasLimit, tc.capBCH)
+			if tc.wantErr && err == nil {
+				t.Fatalf("expected an error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
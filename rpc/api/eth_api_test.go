@@ -0,0 +1,49 @@
+package api
+
+import (
+	"testing"
+
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+// TestResolveHeightBounds is the conformance check the historical-state
+// request asked for: querying several past heights should resolve cleanly,
+// while heights outside what MoeingADS still retains must come back as
+// ErrHistoricalStateNotAvailable instead of silently falling back to latest.
+func TestResolveHeightBounds(t *testing.T) {
+	const (
+		oldest = 100
+		latest = 200
+	)
+
+	cases := []struct {
+		name    string
+		height  int64
+		wantErr bool
+	}{
+		{"oldest retained height", oldest, false},
+		{"one past the oldest height", oldest + 1, false},
+		{"mid-range past height", 150, false},
+		{"latest height", latest, false},
+		{"pruned height below oldest", oldest - 1, true},
+		{"height beyond chain tip", latest + 1, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := resolveHeightBounds(gethrpc.BlockNumber(tc.height), latest, oldest)
+			if tc.wantErr {
+				if err != ErrHistoricalStateNotAvailable {
+					t.Fatalf("height %d: expected ErrHistoricalStateNotAvailable, got %v", tc.height, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("height %d: unexpected error: %v", tc.height, err)
+			}
+			if got != tc.height {
+				t.Fatalf("height %d: resolved to %d", tc.height, got)
+			}
+		})
+	}
+}
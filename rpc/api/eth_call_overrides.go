@@ -0,0 +1,142 @@
+package api
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/smartbch/moeingevm/ebp"
+	sbchapi "github.com/smartbch/smartbch/api"
+	rpctypes "github.com/smartbch/smartbch/rpc/internal/ethapi"
+)
+
+// OverrideAccount is one entry of a StateOverride, matching geth's
+// eth_call extension: every field is optional and only the ones set are
+// applied to the forked snapshot.
+type OverrideAccount struct {
+	Nonce     *hexutil.Uint64              `json:"nonce"`
+	Code      *hexutil.Bytes               `json:"code"`
+	Balance   *hexutil.Big                 `json:"balance"`
+	State     *map[common.Hash]common.Hash `json:"state"`     // replaces all storage
+	StateDiff *map[common.Hash]common.Hash `json:"stateDiff"` // patches individual slots
+}
+
+// StateOverride is the `overrides` param of eth_call: inject synthetic
+// code/balance/storage for a set of addresses before the call runs.
+type StateOverride map[common.Address]OverrideAccount
+
+// BlockOverrides is the `blockOverrides` param of eth_call: bump header
+// fields a contract might read, e.g. a timestamp-gated unlock.
+type BlockOverrides struct {
+	Number     *hexutil.Big    `json:"number"`
+	Difficulty *hexutil.Big    `json:"difficulty"`
+	Time       *hexutil.Big    `json:"time"`
+	GasLimit   *hexutil.Uint64 `json:"gasLimit"`
+	Coinbase   *common.Address `json:"coinbase"`
+	Random     *common.Hash    `json:"random"`
+	BaseFee    *hexutil.Big    `json:"baseFee"`
+}
+
+// CallManyResult is one bundle entry's outcome from CallMany.
+type CallManyResult struct {
+	GasUsed    hexutil.Uint64 `json:"gasUsed"`
+	ReturnData hexutil.Bytes  `json:"returnData,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// https://eth.wiki/json-rpc/API#eth_call flashbots-style bundle extension:
+// runs each bundle entry in order against the *same* forked snapshot, so
+// entry N+1 sees entry N's effects, then reports per-call outcome without
+// ever broadcasting anything. This is the shape MEV/bundle-preview tooling
+// and step-by-step contract debugging both want.
+func (api *ethAPI) CallMany(
+	bundles []rpctypes.CallArgs,
+	overrides *StateOverride,
+	blockOverrides *BlockOverrides,
+) ([]CallManyResult, error) {
+	txs := make([]sbchapi.CallBundleEntry, 0, len(bundles))
+	for _, args := range bundles {
+		tx, from, err := createGethTxFromCallArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, sbchapi.CallBundleEntry{Tx: tx, From: from})
+	}
+
+	raw, err := api.backend.CallManyAtHeight(
+		int64(gethrpc.LatestBlockNumber), txs, toBackendStateOverride(overrides), toBackendBlockOverrides(blockOverrides))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]CallManyResult, 0, len(raw))
+	for _, r := range raw {
+		res := CallManyResult{GasUsed: hexutil.Uint64(r.GasUsed)}
+		if ebp.StatusIsFailure(r.StatusCode) {
+			res.Error = toCallErr(r.StatusCode, r.ReturnData).Error()
+		} else {
+			res.ReturnData = r.ReturnData
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+func toBackendStateOverride(overrides *StateOverride) sbchapi.StateOverride {
+	if overrides == nil {
+		return nil
+	}
+
+	out := make(sbchapi.StateOverride, len(*overrides))
+	for addr, o := range *overrides {
+		acc := sbchapi.OverrideAccount{}
+		if o.Nonce != nil {
+			n := uint64(*o.Nonce)
+			acc.Nonce = &n
+		}
+		if o.Code != nil {
+			acc.Code = []byte(*o.Code)
+		}
+		if o.Balance != nil {
+			acc.Balance = o.Balance.ToInt()
+		}
+		if o.State != nil {
+			acc.State = *o.State
+		}
+		if o.StateDiff != nil {
+			acc.StateDiff = *o.StateDiff
+		}
+		out[addr] = acc
+	}
+	return out
+}
+
+func toBackendBlockOverrides(overrides *BlockOverrides) *sbchapi.BlockOverrides {
+	if overrides == nil {
+		return nil
+	}
+
+	out := &sbchapi.BlockOverrides{}
+	if overrides.Number != nil {
+		out.Number = overrides.Number.ToInt()
+	}
+	if overrides.Difficulty != nil {
+		out.Difficulty = overrides.Difficulty.ToInt()
+	}
+	if overrides.Time != nil {
+		out.Time = overrides.Time.ToInt()
+	}
+	if overrides.GasLimit != nil {
+		out.GasLimit = uint64(*overrides.GasLimit)
+	}
+	if overrides.Coinbase != nil {
+		out.Coinbase = *overrides.Coinbase
+	}
+	if overrides.Random != nil {
+		out.Random = *overrides.Random
+	}
+	if overrides.BaseFee != nil {
+		out.BaseFee = overrides.BaseFee.ToInt()
+	}
+	return out
+}
@@ -0,0 +1,619 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/tendermint/tendermint/libs/log"
+	tmrpc "github.com/tendermint/tendermint/rpc/core"
+
+	sbchapi "github.com/smartbch/smartbch/api"
+	"github.com/smartbch/smartbch/internal/ethutils"
+)
+
+const (
+	// filterTimeout is how long an idle filter is kept around before the
+	// sweeper reclaims it, matching go-ethereum's default.
+	filterTimeout = 5 * time.Minute
+	// filterSweepInterval is how often the idle-filter sweeper runs.
+	filterSweepInterval = 1 * time.Minute
+
+	// subscriptionQueueSize bounds how many undelivered notifications a
+	// single subscription will buffer. The poller (head/log/pending-tx
+	// scan) and the deliverer (notifier.Notify, gated by how fast the
+	// client reads its websocket/IPC connection) run on separate
+	// goroutines connected by this queue, so a slow client can never make
+	// the poller itself block or grow memory without bound.
+	subscriptionQueueSize = 256
+
+	// maxConsecutiveDrops is how many back-to-back notifications a
+	// subscription can fail to queue (because its queue is still full
+	// from the last round) before it's torn down. This is the
+	// back-pressure guard: a client that stops draining its queue falls
+	// further and further behind until it's dropped instead of being
+	// allowed to leak memory forever.
+	maxConsecutiveDrops = 64
+)
+
+var (
+	errFilterNotFound    = errors.New("filter not found")
+	errInvalidBlockRange = errors.New("invalid block range params")
+)
+
+// FilterCriteria mirrors the parameters accepted by eth_newFilter/eth_getLogs.
+type FilterCriteria struct {
+	FromBlock *gethrpc.BlockNumber `json:"fromBlock"`
+	ToBlock   *gethrpc.BlockNumber `json:"toBlock"`
+	Addresses []common.Address     `json:"address"`
+	Topics    [][]common.Hash      `json:"topics"`
+}
+
+type filterKind byte
+
+const (
+	logFilter filterKind = iota
+	blockFilter
+	pendingTxFilter
+)
+
+// trackedFilter holds the server-side cursor for one poll-style filter
+// installed through eth_newFilter/eth_newBlockFilter/eth_newPendingTransactionFilter.
+type trackedFilter struct {
+	kind     filterKind
+	crit     FilterCriteria
+	deadline time.Time
+
+	// cursor for log/block filters: the height already scanned up to
+	// (inclusive).
+	scannedHeight int64
+
+	// cursor for pending-tx filters: hashes already reported as of the
+	// last poll of the Tendermint mempool, so GetFilterChanges only
+	// returns genuinely new arrivals.
+	seenTxHashes map[common.Hash]bool
+}
+
+// PublicFilterAPI is the `eth_` filter and subscription namespace, mirroring
+// ethermint's split of PublicEthAPI into its own sibling service.
+type PublicFilterAPI interface {
+	NewFilter(crit FilterCriteria) (gethrpc.ID, error)
+	NewBlockFilter() gethrpc.ID
+	NewPendingTransactionFilter() gethrpc.ID
+	UninstallFilter(id gethrpc.ID) bool
+	GetFilterChanges(id gethrpc.ID) (interface{}, error)
+	GetFilterLogs(id gethrpc.ID) ([]*gethtypes.Log, error)
+	GetLogs(crit FilterCriteria) ([]*gethtypes.Log, error)
+
+	NewHeads(ctx context.Context) (*gethrpc.Subscription, error)
+	Logs(ctx context.Context, crit FilterCriteria) (*gethrpc.Subscription, error)
+	NewPendingTransactions(ctx context.Context) (*gethrpc.Subscription, error)
+}
+
+var _ PublicFilterAPI = (*filterAPI)(nil)
+
+type filterAPI struct {
+	backend sbchapi.BackendService
+	logger  log.Logger
+
+	mtx     sync.Mutex
+	filters map[gethrpc.ID]*trackedFilter
+
+	stopSweeper chan struct{}
+}
+
+func newFilterAPI(backend sbchapi.BackendService, logger log.Logger) *filterAPI {
+	api := &filterAPI{
+		backend:     backend,
+		logger:      logger.With("module", "filter-api"),
+		filters:     make(map[gethrpc.ID]*trackedFilter),
+		stopSweeper: make(chan struct{}),
+	}
+	go api.sweepIdleFilters()
+	return api
+}
+
+func (api *filterAPI) sweepIdleFilters() {
+	ticker := time.NewTicker(filterSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			now := time.Now()
+			api.mtx.Lock()
+			for id, f := range api.filters {
+				if now.After(f.deadline) {
+					delete(api.filters, id)
+				}
+			}
+			api.mtx.Unlock()
+		case <-api.stopSweeper:
+			return
+		}
+	}
+}
+
+// https://eth.wiki/json-rpc/API#eth_newfilter
+func (api *filterAPI) NewFilter(crit FilterCriteria) (gethrpc.ID, error) {
+	id := gethrpc.NewID()
+	api.mtx.Lock()
+	api.filters[id] = &trackedFilter{
+		kind:          logFilter,
+		crit:          crit,
+		deadline:      time.Now().Add(filterTimeout),
+		scannedHeight: api.backend.LatestHeight(),
+	}
+	api.mtx.Unlock()
+	return id, nil
+}
+
+// https://eth.wiki/json-rpc/API#eth_newblockfilter
+func (api *filterAPI) NewBlockFilter() gethrpc.ID {
+	id := gethrpc.NewID()
+	api.mtx.Lock()
+	api.filters[id] = &trackedFilter{
+		kind:          blockFilter,
+		deadline:      time.Now().Add(filterTimeout),
+		scannedHeight: api.backend.LatestHeight(),
+	}
+	api.mtx.Unlock()
+	return id
+}
+
+// https://eth.wiki/json-rpc/API#eth_newpendingtransactionfilter
+func (api *filterAPI) NewPendingTransactionFilter() gethrpc.ID {
+	id := gethrpc.NewID()
+	api.mtx.Lock()
+	api.filters[id] = &trackedFilter{
+		kind:     pendingTxFilter,
+		deadline: time.Now().Add(filterTimeout),
+	}
+	api.mtx.Unlock()
+	return id
+}
+
+// https://eth.wiki/json-rpc/API#eth_uninstallfilter
+func (api *filterAPI) UninstallFilter(id gethrpc.ID) bool {
+	api.mtx.Lock()
+	defer api.mtx.Unlock()
+	if _, found := api.filters[id]; !found {
+		return false
+	}
+	delete(api.filters, id)
+	return true
+}
+
+// https://eth.wiki/json-rpc/API#eth_getfilterchanges
+func (api *filterAPI) GetFilterChanges(id gethrpc.ID) (interface{}, error) {
+	api.mtx.Lock()
+	f, found := api.filters[id]
+	api.mtx.Unlock()
+	if !found {
+		return nil, errFilterNotFound
+	}
+
+	switch f.kind {
+	case blockFilter:
+		hashes, err := api.pollNewBlocks(f)
+		if err != nil {
+			return nil, err
+		}
+		return hashes, nil
+	case pendingTxFilter:
+		return api.pollNewPendingTxs(f)
+	default:
+		logs, err := api.pollNewLogs(f)
+		if err != nil {
+			return nil, err
+		}
+		return logs, nil
+	}
+}
+
+// https://eth.wiki/json-rpc/API#eth_getfilterlogs
+func (api *filterAPI) GetFilterLogs(id gethrpc.ID) ([]*gethtypes.Log, error) {
+	api.mtx.Lock()
+	f, found := api.filters[id]
+	api.mtx.Unlock()
+	if !found {
+		return nil, errFilterNotFound
+	}
+	if f.kind != logFilter {
+		return nil, errors.New("filter is not a log filter")
+	}
+	return api.getLogs(f.crit)
+}
+
+// https://eth.wiki/json-rpc/API#eth_getlogs
+func (api *filterAPI) GetLogs(crit FilterCriteria) ([]*gethtypes.Log, error) {
+	return api.getLogs(crit)
+}
+
+func (api *filterAPI) pollNewBlocks(f *trackedFilter) ([]common.Hash, error) {
+	latest := api.backend.LatestHeight()
+
+	api.mtx.Lock()
+	from := f.scannedHeight + 1
+	f.scannedHeight = latest
+	f.deadline = time.Now().Add(filterTimeout)
+	api.mtx.Unlock()
+
+	var hashes []common.Hash
+	for h := from; h <= latest; h++ {
+		block, err := api.backend.BlockByNumber(h)
+		if err != nil {
+			continue
+		}
+		hashes = append(hashes, block.Hash)
+	}
+	if hashes == nil {
+		hashes = []common.Hash{}
+	}
+	return hashes, nil
+}
+
+func (api *filterAPI) pollNewLogs(f *trackedFilter) ([]*gethtypes.Log, error) {
+	latest := api.backend.LatestHeight()
+
+	api.mtx.Lock()
+	from := f.scannedHeight + 1
+	crit := f.crit
+	api.mtx.Unlock()
+
+	if from > latest {
+		return []*gethtypes.Log{}, nil
+	}
+
+	fromBlock := gethrpc.BlockNumber(from)
+	toBlock := gethrpc.BlockNumber(latest)
+	crit.FromBlock = &fromBlock
+	crit.ToBlock = &toBlock
+
+	logs, err := api.getLogs(crit)
+	if err != nil {
+		return nil, err
+	}
+
+	api.mtx.Lock()
+	f.scannedHeight = latest
+	f.deadline = time.Now().Add(filterTimeout)
+	api.mtx.Unlock()
+
+	return logs, nil
+}
+
+// pollNewPendingTxs diffs the Tendermint mempool's current contents against
+// f's last-seen set, returning only hashes that showed up since the last
+// poll. The seen set is replaced (not just grown) with this poll's snapshot
+// each time, so a tx that leaves the mempool (mined or evicted) stops being
+// tracked instead of leaking memory for the life of the filter.
+func (api *filterAPI) pollNewPendingTxs(f *trackedFilter) ([]common.Hash, error) {
+	result, err := tmrpc.UnconfirmedTxs(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[common.Hash]bool, len(result.Txs))
+	fresh := []common.Hash{}
+
+	api.mtx.Lock()
+	defer api.mtx.Unlock()
+
+	for _, raw := range result.Txs {
+		tx, err := ethutils.DecodeTx(raw)
+		if err != nil {
+			continue
+		}
+		hash := tx.Hash()
+		current[hash] = true
+		if !f.seenTxHashes[hash] {
+			fresh = append(fresh, hash)
+		}
+	}
+
+	f.seenTxHashes = current
+	f.deadline = time.Now().Add(filterTimeout)
+	return fresh, nil
+}
+
+// getLogs walks [fromBlock, toBlock], using each block header's bloom filter
+// to skip blocks that cannot possibly contain a match before paying the cost
+// of loading and decoding that block's receipts.
+func (api *filterAPI) getLogs(crit FilterCriteria) ([]*gethtypes.Log, error) {
+	from, to, err := api.resolveFilterRange(crit)
+	if err != nil {
+		return nil, err
+	}
+
+	logs := make([]*gethtypes.Log, 0)
+	for h := from; h <= to; h++ {
+		block, err := api.backend.BlockByNumber(h)
+		if err != nil {
+			continue
+		}
+
+		bloom := gethtypes.BytesToBloom(block.LogsBloom)
+		if !bloomMayMatch(bloom, crit) {
+			continue
+		}
+
+		blockLogs, err := api.backend.GetLogsByHeight(h)
+		if err != nil {
+			continue
+		}
+		logs = append(logs, filterLogs(blockLogs, crit.Addresses, crit.Topics)...)
+	}
+	return logs, nil
+}
+
+func (api *filterAPI) resolveFilterRange(crit FilterCriteria) (int64, int64, error) {
+	latest := api.backend.LatestHeight()
+
+	from := latest
+	if crit.FromBlock != nil && crit.FromBlock.Int64() >= 0 {
+		from = crit.FromBlock.Int64()
+	}
+	to := latest
+	if crit.ToBlock != nil && crit.ToBlock.Int64() >= 0 {
+		to = crit.ToBlock.Int64()
+	}
+	if from > to {
+		return 0, 0, errInvalidBlockRange
+	}
+	return from, to, nil
+}
+
+// bloomMayMatch reports whether a block's header bloom could possibly
+// contain logs matching crit; false means the block can be skipped outright.
+func bloomMayMatch(bloom gethtypes.Bloom, crit FilterCriteria) bool {
+	if len(crit.Addresses) > 0 {
+		found := false
+		for _, addr := range crit.Addresses {
+			if bloom.Test(addr.Bytes()) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for _, topicSet := range crit.Topics {
+		if len(topicSet) == 0 {
+			continue
+		}
+		found := false
+		for _, topic := range topicSet {
+			if bloom.Test(topic.Bytes()) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// filterLogs applies the address/topic wildcarding rules from eth_getLogs:
+// an empty Addresses list matches any address, and each position in Topics
+// is OR'd across its own hashes while every position must match (empty
+// position means "any").
+func filterLogs(logs []*gethtypes.Log, addresses []common.Address, topics [][]common.Hash) []*gethtypes.Log {
+	var out []*gethtypes.Log
+	for _, lg := range logs {
+		if len(addresses) > 0 && !containsAddress(addresses, lg.Address) {
+			continue
+		}
+		if !matchesTopics(lg.Topics, topics) {
+			continue
+		}
+		out = append(out, lg)
+	}
+	return out
+}
+
+func containsAddress(addresses []common.Address, addr common.Address) bool {
+	for _, a := range addresses {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesTopics(logTopics []common.Hash, wanted [][]common.Hash) bool {
+	if len(wanted) > len(logTopics) {
+		return false
+	}
+	for i, topicSet := range wanted {
+		if len(topicSet) == 0 {
+			continue // wildcard
+		}
+		match := false
+		for _, t := range topicSet {
+			if logTopics[i] == t {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
+
+// subscriptionSender decouples a subscription's poll loop (producer) from
+// notifier.Notify (consumer, paced by the client's own read speed) with a
+// bounded channel: offer() never blocks the poller, and once the queue is
+// full for maxConsecutiveDrops deliveries in a row, offer reports the
+// subscription as stuck so the caller can tear it down.
+type subscriptionSender struct {
+	queue chan interface{}
+	drops int
+}
+
+func newSubscriptionSender() *subscriptionSender {
+	return &subscriptionSender{queue: make(chan interface{}, subscriptionQueueSize)}
+}
+
+// offer enqueues payload without blocking, dropping it if the queue is
+// still full from a slow client. It returns false once the subscription has
+// fallen behind for maxConsecutiveDrops straight deliveries and should stop.
+func (s *subscriptionSender) offer(payload interface{}) bool {
+	select {
+	case s.queue <- payload:
+		s.drops = 0
+	default:
+		s.drops++
+	}
+	return s.drops < maxConsecutiveDrops
+}
+
+// run delivers queued payloads with deliver until the queue is closed.
+func (s *subscriptionSender) run(deliver func(interface{})) {
+	for payload := range s.queue {
+		deliver(payload)
+	}
+}
+
+func (s *subscriptionSender) close() {
+	close(s.queue)
+}
+
+// https://eth.wiki/json-rpc/API#eth_subscribe (newHeads)
+func (api *filterAPI) NewHeads(ctx context.Context) (*gethrpc.Subscription, error) {
+	notifier, supported := gethrpc.NotifierFromContext(ctx)
+	if !supported {
+		return &gethrpc.Subscription{}, gethrpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	go api.runHeadSubscription(notifier, rpcSub)
+	return rpcSub, nil
+}
+
+func (api *filterAPI) runHeadSubscription(notifier *gethrpc.Notifier, rpcSub *gethrpc.Subscription) {
+	lastHeight := api.backend.LatestHeight()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	sender := newSubscriptionSender()
+	defer sender.close()
+	go sender.run(func(payload interface{}) { _ = notifier.Notify(rpcSub.ID, payload) })
+
+	for {
+		select {
+		case <-ticker.C:
+			height := api.backend.LatestHeight()
+			for h := lastHeight + 1; h <= height; h++ {
+				block, err := api.backend.BlockByNumber(h)
+				if err != nil {
+					continue
+				}
+				if !sender.offer(blockToRpcResp(block, nil)) {
+					api.logger.Error("newHeads subscriber fell too far behind, unsubscribing", "id", rpcSub.ID)
+					return
+				}
+			}
+			lastHeight = height
+		case <-rpcSub.Err():
+			return
+		case <-notifier.Closed():
+			return
+		}
+	}
+}
+
+// https://eth.wiki/json-rpc/API#eth_subscribe (logs)
+func (api *filterAPI) Logs(ctx context.Context, crit FilterCriteria) (*gethrpc.Subscription, error) {
+	notifier, supported := gethrpc.NotifierFromContext(ctx)
+	if !supported {
+		return &gethrpc.Subscription{}, gethrpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	go api.runLogSubscription(notifier, rpcSub, crit)
+	return rpcSub, nil
+}
+
+func (api *filterAPI) runLogSubscription(notifier *gethrpc.Notifier, rpcSub *gethrpc.Subscription, crit FilterCriteria) {
+	f := &trackedFilter{kind: logFilter, crit: crit, scannedHeight: api.backend.LatestHeight()}
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	sender := newSubscriptionSender()
+	defer sender.close()
+	go sender.run(func(payload interface{}) { _ = notifier.Notify(rpcSub.ID, payload) })
+
+	for {
+		select {
+		case <-ticker.C:
+			logs, err := api.pollNewLogs(f)
+			if err != nil {
+				continue
+			}
+			for _, lg := range logs {
+				if !sender.offer(lg) {
+					api.logger.Error("logs subscriber fell too far behind, unsubscribing", "id", rpcSub.ID)
+					return
+				}
+			}
+		case <-rpcSub.Err():
+			return
+		case <-notifier.Closed():
+			return
+		}
+	}
+}
+
+// https://eth.wiki/json-rpc/API#eth_subscribe (newPendingTransactions)
+func (api *filterAPI) NewPendingTransactions(ctx context.Context) (*gethrpc.Subscription, error) {
+	notifier, supported := gethrpc.NotifierFromContext(ctx)
+	if !supported {
+		return &gethrpc.Subscription{}, gethrpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	go api.runPendingTxSubscription(notifier, rpcSub)
+	return rpcSub, nil
+}
+
+func (api *filterAPI) runPendingTxSubscription(notifier *gethrpc.Notifier, rpcSub *gethrpc.Subscription) {
+	f := &trackedFilter{kind: pendingTxFilter}
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	sender := newSubscriptionSender()
+	defer sender.close()
+	go sender.run(func(payload interface{}) { _ = notifier.Notify(rpcSub.ID, payload) })
+
+	for {
+		select {
+		case <-ticker.C:
+			hashes, err := api.pollNewPendingTxs(f)
+			if err != nil {
+				continue
+			}
+			for _, hash := range hashes {
+				if !sender.offer(hash) {
+					api.logger.Error("pending-tx subscriber fell too far behind, unsubscribing", "id", rpcSub.ID)
+					return
+				}
+			}
+		case <-rpcSub.Err():
+			return
+		case <-notifier.Closed():
+			return
+		}
+	}
+}
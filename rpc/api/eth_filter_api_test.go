@@ -0,0 +1,114 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// TestSubscriptionSenderBackpressure is the back-pressure conformance check
+// the filter-subscription request asked for: offer() must never block the
+// producer, a full queue gets drained independently, and a consumer that
+// never drains at all (the stuck-client case) makes offer() report the
+// subscription as dead after maxConsecutiveDrops straight drops, instead of
+// queuing forever.
+func TestSubscriptionSenderBackpressure(t *testing.T) {
+	t.Run("drains a keeping-up consumer without ever reporting stuck", func(t *testing.T) {
+		s := newSubscriptionSender()
+		delivered := make(chan interface{}, subscriptionQueueSize*2)
+		go s.run(func(payload interface{}) { delivered <- payload })
+		defer s.close()
+
+		for i := 0; i < subscriptionQueueSize*2; i++ {
+			if !s.offer(i) {
+				t.Fatalf("offer(%d) reported the subscription as stuck, want it to keep accepting", i)
+			}
+			<-delivered // keep up: drain as fast as it's produced
+		}
+	})
+
+	t.Run("a stuck consumer is torn down after maxConsecutiveDrops", func(t *testing.T) {
+		s := newSubscriptionSender()
+		// No reader is ever started: every item past the queue's capacity
+		// must be dropped, since offer() must not block.
+
+		for i := 0; i < subscriptionQueueSize; i++ {
+			if !s.offer(i) {
+				t.Fatalf("offer(%d) reported stuck before the queue even filled", i)
+			}
+		}
+
+		stuck := false
+		for i := 0; i < maxConsecutiveDrops; i++ {
+			if !s.offer(i) {
+				stuck = true
+				break
+			}
+		}
+		if !stuck {
+			t.Fatalf("offer() never reported the subscription as stuck after %d consecutive drops", maxConsecutiveDrops)
+		}
+	})
+}
+
+// TestMatchesTopicsWildcarding is the topic-wildcarding conformance check the
+// request asked for: an empty position in the filter's Topics matches any
+// topic at that index, a non-empty position must match one of its hashes
+// (OR'd), and every position present must match (AND'd across positions).
+//
+// Note: this suite doesn't cover reorg safety — that needs a live/mock
+// sbchapi.BackendService to drive height changes through pollNewLogs, and
+// this trimmed tree has no fake BackendService to stand one up.
+// subscriptionSender's back-pressure guard is backend-independent, so that
+// one is covered above in TestSubscriptionSenderBackpressure.
+func TestMatchesTopicsWildcarding(t *testing.T) {
+	t1 := common.HexToHash("0x1")
+	t2 := common.HexToHash("0x2")
+	t3 := common.HexToHash("0x3")
+	other := common.HexToHash("0x9")
+
+	cases := []struct {
+		name      string
+		logTopics []common.Hash
+		wanted    [][]common.Hash
+		want      bool
+	}{
+		{"no filter matches anything", []common.Hash{t1, t2}, nil, true},
+		{"wildcard position matches any topic", []common.Hash{t1, t2}, [][]common.Hash{{}, {t2}}, true},
+		{"OR within a position", []common.Hash{t1}, [][]common.Hash{{t2, t1, t3}}, true},
+		{"mismatch at a required position", []common.Hash{t1, t2}, [][]common.Hash{{t1}, {other}}, false},
+		{"more positions wanted than log has topics", []common.Hash{t1}, [][]common.Hash{{t1}, {t2}}, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := matchesTopics(tc.logTopics, tc.wanted); got != tc.want {
+				t.Fatalf("matchesTopics(%v, %v) = %v, want %v", tc.logTopics, tc.wanted, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFilterLogsAddressAndTopics(t *testing.T) {
+	addrA := common.HexToAddress("0xaa")
+	addrB := common.HexToAddress("0xbb")
+	t1 := common.HexToHash("0x1")
+	t2 := common.HexToHash("0x2")
+
+	logs := []*gethtypes.Log{
+		{Address: addrA, Topics: []common.Hash{t1}},
+		{Address: addrB, Topics: []common.Hash{t1}},
+		{Address: addrA, Topics: []common.Hash{t2}},
+	}
+
+	got := filterLogs(logs, []common.Address{addrA}, [][]common.Hash{{t1}})
+	if len(got) != 1 || got[0] != logs[0] {
+		t.Fatalf("filterLogs returned %v, want just logs[0]", got)
+	}
+
+	gotAny := filterLogs(logs, nil, nil)
+	if len(gotAny) != len(logs) {
+		t.Fatalf("filterLogs with no criteria dropped logs: got %d, want %d", len(gotAny), len(logs))
+	}
+}
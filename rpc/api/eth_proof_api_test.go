@@ -0,0 +1,98 @@
+package api
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// TestProveSingleLeafRoundTrips is the verifier test the eth_getProof request
+// asked for: a proof built the same way GetProof builds its account proof
+// must round-trip through go-ethereum's trie.VerifyProof and yield back the
+// exact RLP-encoded leaf that was inserted. This is what light wallets and
+// bridges actually run against the accountProof/storageProof arrays, so it's
+// the one check that catches a subtly wrong proof before it ships.
+func TestProveSingleLeafRoundTrips(t *testing.T) {
+	key := crypto.Keccak256(common.HexToAddress("0x1234").Bytes())
+	acc := &gethtypes.StateAccount{
+		Nonce:    7,
+		Balance:  big.NewInt(1000000000000000000),
+		Root:     common.Hash{},
+		CodeHash: crypto.Keccak256(nil),
+	}
+	wantEncoded, err := rlp.EncodeToBytes(acc)
+	if err != nil {
+		t.Fatalf("rlp.EncodeToBytes: %v", err)
+	}
+
+	db := trie.NewDatabase(memorydb.New())
+	tr, err := trie.New(common.Hash{}, db)
+	if err != nil {
+		t.Fatalf("trie.New: %v", err)
+	}
+	if err := tr.TryUpdate(key, wantEncoded); err != nil {
+		t.Fatalf("TryUpdate: %v", err)
+	}
+	root := tr.Hash()
+
+	nodes, err := proveSingleLeaf(key, acc)
+	if err != nil {
+		t.Fatalf("proveSingleLeaf: %v", err)
+	}
+	proofDB := proofDBFromNodes(t, nodes)
+
+	got, err := trie.VerifyProof(root, key, proofDB)
+	if err != nil {
+		t.Fatalf("VerifyProof: %v", err)
+	}
+	if !bytes.Equal(got, wantEncoded) {
+		t.Fatalf("VerifyProof returned %x, want %x", got, wantEncoded)
+	}
+}
+
+// TestProveSingleLeafRejectsTamperedRoot checks the negative case: verifying
+// against the wrong root must fail instead of silently returning a value, or
+// a bridge trusting eth_getProof would accept a proof against a forged root.
+func TestProveSingleLeafRejectsTamperedRoot(t *testing.T) {
+	key := crypto.Keccak256(common.HexToAddress("0x1234").Bytes())
+	nodes, err := proveSingleLeaf(key, &gethtypes.StateAccount{
+		Nonce:    1,
+		Balance:  big.NewInt(1),
+		Root:     common.Hash{},
+		CodeHash: crypto.Keccak256(nil),
+	})
+	if err != nil {
+		t.Fatalf("proveSingleLeaf: %v", err)
+	}
+	proofDB := proofDBFromNodes(t, nodes)
+
+	wrongRoot := common.HexToHash("0xdeadbeef")
+	if _, err := trie.VerifyProof(wrongRoot, key, proofDB); err == nil {
+		t.Fatalf("expected VerifyProof against a tampered root to fail")
+	}
+}
+
+// proofDBFromNodes loads the hex-encoded nodes returned by proveSingleLeaf
+// into a store keyed by node hash, the form trie.VerifyProof reads.
+func proofDBFromNodes(t *testing.T, nodes []string) *memorydb.Database {
+	t.Helper()
+	db := memorydb.New()
+	for _, n := range nodes {
+		blob, err := hexutil.Decode(n)
+		if err != nil {
+			t.Fatalf("hexutil.Decode(%s): %v", n, err)
+		}
+		if err := db.Put(crypto.Keccak256(blob), blob); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	return db
+}
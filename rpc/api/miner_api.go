@@ -0,0 +1,45 @@
+package api
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	sbchapi "github.com/smartbch/smartbch/api"
+)
+
+// PublicMinerAPI is the `miner_` namespace. smartbch has no PoW miner; these
+// are stubs kept consistent with its block producer so tooling that probes
+// miner_* doesn't error out.
+type PublicMinerAPI interface {
+	SetGasPrice(gasPrice hexutil.Big) bool
+	GetHashrate() hexutil.Uint64
+	SetEtherbase(addr common.Address) bool
+}
+
+var _ PublicMinerAPI = (*minerAPI)(nil)
+
+type minerAPI struct {
+	backend sbchapi.BackendService
+}
+
+func newMinerAPI(backend sbchapi.BackendService) *minerAPI {
+	return &minerAPI{backend: backend}
+}
+
+// https://geth.ethereum.org/docs/rpc/ns-miner#minersetgasprice
+func (api *minerAPI) SetGasPrice(gasPrice hexutil.Big) bool {
+	// smartbch's gas price is a consensus parameter, not locally tunable.
+	return false
+}
+
+// https://geth.ethereum.org/docs/rpc/ns-miner#minergethashrate
+func (api *minerAPI) GetHashrate() hexutil.Uint64 {
+	// smartbch's block producer is Tendermint BFT, not PoW.
+	return 0
+}
+
+// https://geth.ethereum.org/docs/rpc/ns-miner#minersetetherbase
+func (api *minerAPI) SetEtherbase(addr common.Address) bool {
+	// no local coinbase to set; smartbch's block rewards go to validators.
+	return false
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"fmt"
+
+	tmrpc "github.com/tendermint/tendermint/rpc/core"
+
+	sbchapi "github.com/smartbch/smartbch/api"
+)
+
+// PublicNetAPI is the `net_` namespace.
+type PublicNetAPI interface {
+	Version() string
+	Listening() bool
+	PeerCount() int
+}
+
+var _ PublicNetAPI = (*netAPI)(nil)
+
+type netAPI struct {
+	backend sbchapi.BackendService
+}
+
+func newNetAPI(backend sbchapi.BackendService) *netAPI {
+	return &netAPI{backend: backend}
+}
+
+// https://eth.wiki/json-rpc/API#net_version
+func (api *netAPI) Version() string {
+	return fmt.Sprintf("%d", api.backend.ChainId().Uint64())
+}
+
+// https://eth.wiki/json-rpc/API#net_listening
+func (api *netAPI) Listening() bool {
+	status, err := tmrpc.Status(nil)
+	if err != nil {
+		return false
+	}
+	return !status.SyncInfo.CatchingUp
+}
+
+// https://eth.wiki/json-rpc/API#net_peercount
+func (api *netAPI) PeerCount() int {
+	result, err := tmrpc.NetInfo(nil)
+	if err != nil {
+		return 0
+	}
+	return result.NPeers
+}
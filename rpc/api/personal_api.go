@@ -0,0 +1,190 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/tendermint/tendermint/libs/log"
+
+	"github.com/smartbch/smartbch/internal/ethutils"
+	rpctypes "github.com/smartbch/smartbch/rpc/internal/ethapi"
+)
+
+// defaultUnlockDuration mirrors geth's personal_unlockAccount default when
+// the caller omits the duration.
+const defaultUnlockDuration = 300 * time.Second
+
+// PublicPersonalAPI is the `personal_` namespace, split out of PublicEthAPI
+// so wallet-management concerns (accounts, signing, unlocking) live apart
+// from chain-reading concerns.
+type PublicPersonalAPI interface {
+	Accounts() []common.Address
+	NewAccount(passphrase string) (common.Address, error)
+	ImportRawKey(privKey, passphrase string) (common.Address, error)
+	UnlockAccount(addr common.Address, passphrase string, duration *uint64) (bool, error)
+	LockAccount(addr common.Address) bool
+	Sign(data hexutil.Bytes, addr common.Address, passphrase string) (hexutil.Bytes, error)
+	EcRecover(data, sig hexutil.Bytes) (common.Address, error)
+	SendTransaction(args rpctypes.SendTxArgs, passphrase string) (common.Hash, error)
+}
+
+var _ PublicPersonalAPI = (*personalAPI)(nil)
+
+type personalAPI struct {
+	backend  sbchBackend
+	keystore *keystore.KeyStore
+	logger   log.Logger
+}
+
+// sbchBackend is the subset of sbchapi.BackendService that personalAPI
+// needs; kept narrow so this file doesn't have to import the full backend
+// surface used by ethAPI.
+type sbchBackend interface {
+	ChainId() *big.Int
+	GetNonce(addr common.Address) (uint64, error)
+	SendRawTx(data hexutil.Bytes) (common.Hash, error)
+}
+
+// newPersonalAPI backs personal_ by a geth-compatible keystore rather than
+// the old in-memory testKeys map, so wallets created through
+// personal_newAccount survive a node restart the same way geth's do. ks is
+// the single instance GetAPIs also hands to eth_, so an account unlocked
+// here is unlocked for eth_resend too.
+func newPersonalAPI(backend sbchBackend, ks *keystore.KeyStore, logger log.Logger) *personalAPI {
+	return &personalAPI{
+		backend:  backend,
+		keystore: ks,
+		logger:   logger.With("module", "personal-api"),
+	}
+}
+
+// newKeystoreAt opens (creating if needed) the geth-compatible keystore
+// directory backing personal_ and eth_resend. Callers
+// must open it exactly once and share the resulting instance between those
+// namespaces: geth's KeyStore keeps unlock state in memory, not on disk, so
+// two instances over the same directory never see each other's unlocks.
+func newKeystoreAt(dir string) *keystore.KeyStore {
+	return keystore.NewKeyStore(dir, keystore.StandardScryptN, keystore.StandardScryptP)
+}
+
+// https://eth.wiki/json-rpc/API#personal_listaccounts
+func (api *personalAPI) Accounts() []common.Address {
+	addrs := make([]common.Address, 0, len(api.keystore.Accounts()))
+	for _, acc := range api.keystore.Accounts() {
+		addrs = append(addrs, acc.Address)
+	}
+	return addrs
+}
+
+// https://eth.wiki/json-rpc/API#personal_newaccount
+func (api *personalAPI) NewAccount(passphrase string) (common.Address, error) {
+	acc, err := api.keystore.NewAccount(passphrase)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return acc.Address, nil
+}
+
+// https://eth.wiki/json-rpc/API#personal_importrawkey
+func (api *personalAPI) ImportRawKey(privKey, passphrase string) (common.Address, error) {
+	key, _, err := ethutils.HexToPrivKey(privKey)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	acc, err := api.keystore.ImportECDSA(key, passphrase)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return acc.Address, nil
+}
+
+// https://eth.wiki/json-rpc/API#personal_unlockaccount
+func (api *personalAPI) UnlockAccount(addr common.Address, passphrase string, duration *uint64) (bool, error) {
+	d := defaultUnlockDuration
+	if duration != nil {
+		d = time.Duration(*duration) * time.Second
+	}
+
+	acc := accounts.Account{Address: addr}
+	if err := api.keystore.TimedUnlock(acc, passphrase, d); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// https://eth.wiki/json-rpc/API#personal_lockaccount
+func (api *personalAPI) LockAccount(addr common.Address) bool {
+	if err := api.keystore.Lock(addr); err != nil {
+		return false
+	}
+	return true
+}
+
+// https://eth.wiki/json-rpc/API#personal_sign
+func (api *personalAPI) Sign(data hexutil.Bytes, addr common.Address, passphrase string) (hexutil.Bytes, error) {
+	acc := accounts.Account{Address: addr}
+	sig, err := api.keystore.SignHashWithPassphrase(acc, passphrase, accounts.TextHash(data))
+	if err != nil {
+		return nil, err
+	}
+	// personal_sign uses the legacy 27/28 recovery id, unlike eth_sign.
+	sig[64] += 27
+	return sig, nil
+}
+
+// https://eth.wiki/json-rpc/API#personal_ecrecover
+func (api *personalAPI) EcRecover(data, sig hexutil.Bytes) (common.Address, error) {
+	if len(sig) != 65 {
+		return common.Address{}, errors.New("signature must be 65 bytes long")
+	}
+	if sig[64] != 27 && sig[64] != 28 {
+		return common.Address{}, errors.New("invalid signature recovery id")
+	}
+
+	sigCopy := make([]byte, len(sig))
+	copy(sigCopy, sig)
+	sigCopy[64] -= 27
+
+	pubKey, err := crypto.SigToPub(accounts.TextHash(data), sigCopy)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pubKey), nil
+}
+
+// https://eth.wiki/json-rpc/API#personal_sendtransaction
+func (api *personalAPI) SendTransaction(args rpctypes.SendTxArgs, passphrase string) (common.Hash, error) {
+	if args.Nonce == nil {
+		if nonce, err := api.backend.GetNonce(args.From); err == nil {
+			args.Nonce = (*hexutil.Uint64)(&nonce)
+		}
+	}
+
+	tx, err := createGethTxFromSendTxArgs(args)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	acc := accounts.Account{Address: args.From}
+	chainID := api.backend.ChainId()
+	tx, err = api.keystore.SignTxWithPassphrase(acc, passphrase, tx, chainID)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to unlock account %s: %w", args.From.Hex(), err)
+	}
+
+	txBytes, err := ethutils.EncodeTx(tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return api.backend.SendRawTx(txBytes)
+}
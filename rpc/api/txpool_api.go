@@ -0,0 +1,120 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	tmrpc "github.com/tendermint/tendermint/rpc/core"
+
+	"github.com/smartbch/smartbch/internal/ethutils"
+)
+
+// PublicTxPoolAPI is the `txpool_` namespace, backed by the Tendermint
+// mempool rather than a geth-style pending-tx pool.
+type PublicTxPoolAPI interface {
+	Status() map[string]hexutil.Uint
+	Content() map[string]map[string]map[string]*rpcTxInfo
+	Inspect() map[string]map[string]map[string]string
+}
+
+var _ PublicTxPoolAPI = (*txPoolAPI)(nil)
+
+type txPoolAPI struct{}
+
+func newTxPoolAPI() *txPoolAPI {
+	return &txPoolAPI{}
+}
+
+// rpcTxInfo is the per-tx shape returned by txpool_content, abbreviated to
+// the fields smartbch can actually populate from a raw mempool entry.
+type rpcTxInfo struct {
+	From     string         `json:"from"`
+	Nonce    hexutil.Uint64 `json:"nonce"`
+	GasPrice *hexutil.Big   `json:"gasPrice"`
+	Gas      hexutil.Uint64 `json:"gas"`
+}
+
+// https://eth.wiki/json-rpc/API#txpool_status
+func (api *txPoolAPI) Status() map[string]hexutil.Uint {
+	result, err := tmrpc.UnconfirmedTxs(nil, nil)
+	if err != nil {
+		return map[string]hexutil.Uint{"pending": 0, "queued": 0}
+	}
+	return map[string]hexutil.Uint{
+		"pending": hexutil.Uint(result.Total),
+		// smartbch/Tendermint has no separate not-yet-executable queue.
+		"queued": 0,
+	}
+}
+
+// https://eth.wiki/json-rpc/API#txpool_content
+func (api *txPoolAPI) Content() map[string]map[string]map[string]*rpcTxInfo {
+	pending := map[string]map[string]*rpcTxInfo{}
+
+	result, err := tmrpc.UnconfirmedTxs(nil, nil)
+	if err == nil {
+		for _, raw := range result.Txs {
+			tx, err := ethutils.DecodeTx(raw)
+			if err != nil {
+				continue
+			}
+			from, err := ethutils.Sender(tx)
+			if err != nil {
+				continue
+			}
+
+			info := &rpcTxInfo{
+				From:     from.Hex(),
+				Nonce:    hexutil.Uint64(tx.Nonce()),
+				GasPrice: (*hexutil.Big)(tx.GasPrice()),
+				Gas:      hexutil.Uint64(tx.Gas()),
+			}
+			if pending[from.Hex()] == nil {
+				pending[from.Hex()] = map[string]*rpcTxInfo{}
+			}
+			pending[from.Hex()][info.Nonce.String()] = info
+		}
+	}
+
+	return map[string]map[string]map[string]*rpcTxInfo{
+		"pending": pending,
+		"queued":  {},
+	}
+}
+
+// https://eth.wiki/json-rpc/API#txpool_inspect
+func (api *txPoolAPI) Inspect() map[string]map[string]map[string]string {
+	pending := map[string]map[string]string{}
+
+	result, err := tmrpc.UnconfirmedTxs(nil, nil)
+	if err == nil {
+		for _, raw := range result.Txs {
+			tx, err := ethutils.DecodeTx(raw)
+			if err != nil {
+				continue
+			}
+			from, err := ethutils.Sender(tx)
+			if err != nil {
+				continue
+			}
+
+			to := "contract creation"
+			if tx.To() != nil {
+				to = tx.To().Hex()
+			}
+
+			if pending[from.Hex()] == nil {
+				pending[from.Hex()] = map[string]string{}
+			}
+			nonce := hexutil.Uint64(tx.Nonce()).String()
+			pending[from.Hex()][nonce] = fmt.Sprintf("%s: %v wei + %v gas x %v wei",
+				to, tx.Value(), tx.Gas(), tx.GasPrice())
+		}
+	}
+
+	return map[string]map[string]map[string]string{
+		"pending": pending,
+		"queued":  {},
+	}
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// PublicWeb3API is the `web3_` namespace.
+type PublicWeb3API interface {
+	ClientVersion() string
+	Sha3(input hexutil.Bytes) hexutil.Bytes
+}
+
+var _ PublicWeb3API = (*web3API)(nil)
+
+type web3API struct{}
+
+func newWeb3API() *web3API {
+	return &web3API{}
+}
+
+// https://eth.wiki/json-rpc/API#web3_clientversion
+func (api *web3API) ClientVersion() string {
+	return "smartbch/" + params.VersionWithCommit("", "")
+}
+
+// https://eth.wiki/json-rpc/API#web3_sha3
+func (api *web3API) Sha3(input hexutil.Bytes) hexutil.Bytes {
+	return crypto.Keccak256(input)
+}